@@ -0,0 +1,58 @@
+// Copyright (c) 2019 Minoru Osuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/grpclog"
+)
+
+// grpcLogger adapts a Logger to grpclog.LoggerV2.
+type grpcLogger struct {
+	Logger
+}
+
+// AsGRPCLoggerV2 wraps logger for use with grpclog.SetLoggerV2.
+func AsGRPCLoggerV2(logger Logger) grpclog.LoggerV2 {
+	return grpcLogger{Logger: logger}
+}
+
+func (g grpcLogger) Infoln(args ...interface{})    { g.Info(args...) }
+func (g grpcLogger) Warningln(args ...interface{}) { g.Warning(args...) }
+func (g grpcLogger) Errorln(args ...interface{})   { g.Error(args...) }
+func (g grpcLogger) Fatalln(args ...interface{})   { g.Fatal(args...) }
+func (g grpcLogger) V(l int) bool                  { return bool(g.Logger.V(l)) }
+
+// Writer adapts a Logger to io.Writer for callers (e.g. hashicorp/raft's
+// LogOutput) that only write pre-formatted, already-leveled lines.
+type Writer struct {
+	Logger
+}
+
+func (w Writer) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p), "\n")
+
+	switch {
+	case strings.Contains(line, "[ERR]"), strings.Contains(line, "[ERROR]"):
+		w.Logger.Error(line)
+	case strings.Contains(line, "[WARN]"):
+		w.Logger.Warning(line)
+	default:
+		w.Logger.Info(line)
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2019 Minoru Osuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package log is blast's leveled/verbosity-aware logging façade.
+package log
+
+import (
+	"io"
+	"log"
+	"os"
+	"strconv"
+)
+
+// Severity identifies how important a log line is.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityFatal
+)
+
+func severityFromString(s string) Severity {
+	switch s {
+	case "warning":
+		return SeverityWarning
+	case "error":
+		return SeverityError
+	case "fatal":
+		return SeverityFatal
+	default:
+		return SeverityInfo
+	}
+}
+
+// Verbose reports whether a V-gated line should be logged.
+type Verbose bool
+
+// Logger replaces the standard library's *log.Logger across blast.
+type Logger interface {
+	Info(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warning(args ...interface{})
+	Warningf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	// V reports whether verbosity level v is enabled for this logger.
+	V(level int) Verbose
+}
+
+type loggerT struct {
+	infoLog    *log.Logger
+	warningLog *log.Logger
+	errorLog   *log.Logger
+	fatalLog   *log.Logger
+
+	severity  Severity
+	verbosity int
+}
+
+// New creates a Logger writing INFO/WARNING to out and ERROR/FATAL to err.
+func New(out, err io.Writer, severity Severity, verbosity int) Logger {
+	flags := log.LstdFlags
+	return &loggerT{
+		infoLog:    log.New(out, "[INFO] ", flags),
+		warningLog: log.New(out, "[WARN] ", flags),
+		errorLog:   log.New(err, "[ERR] ", flags),
+		fatalLog:   log.New(err, "[FATAL] ", flags),
+		severity:   severity,
+		verbosity:  verbosity,
+	}
+}
+
+// NewFromEnv builds the default Logger from BLAST_LOG_SEVERITY_LEVEL and
+// BLAST_LOG_VERBOSITY_LEVEL.
+func NewFromEnv() Logger {
+	severity := severityFromString(os.Getenv("BLAST_LOG_SEVERITY_LEVEL"))
+
+	verbosity := 0
+	if v, err := strconv.Atoi(os.Getenv("BLAST_LOG_VERBOSITY_LEVEL")); err == nil {
+		verbosity = v
+	}
+
+	return New(os.Stdout, os.Stderr, severity, verbosity)
+}
+
+func (l *loggerT) Info(args ...interface{}) {
+	if l.severity <= SeverityInfo {
+		l.infoLog.Print(args...)
+	}
+}
+
+func (l *loggerT) Infof(format string, args ...interface{}) {
+	if l.severity <= SeverityInfo {
+		l.infoLog.Printf(format, args...)
+	}
+}
+
+func (l *loggerT) Warning(args ...interface{}) {
+	if l.severity <= SeverityWarning {
+		l.warningLog.Print(args...)
+	}
+}
+
+func (l *loggerT) Warningf(format string, args ...interface{}) {
+	if l.severity <= SeverityWarning {
+		l.warningLog.Printf(format, args...)
+	}
+}
+
+func (l *loggerT) Error(args ...interface{}) {
+	if l.severity <= SeverityError {
+		l.errorLog.Print(args...)
+	}
+}
+
+func (l *loggerT) Errorf(format string, args ...interface{}) {
+	if l.severity <= SeverityError {
+		l.errorLog.Printf(format, args...)
+	}
+}
+
+func (l *loggerT) Fatal(args ...interface{}) {
+	l.fatalLog.Print(args...)
+	os.Exit(1)
+}
+
+func (l *loggerT) Fatalf(format string, args ...interface{}) {
+	l.fatalLog.Printf(format, args...)
+	os.Exit(1)
+}
+
+func (l *loggerT) V(level int) Verbose {
+	return Verbose(level <= l.verbosity)
+}
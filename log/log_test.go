@@ -0,0 +1,76 @@
+// Copyright (c) 2019 Minoru Osuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSeverityFromString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Severity
+	}{
+		{"warning", SeverityWarning},
+		{"error", SeverityError},
+		{"fatal", SeverityFatal},
+		{"info", SeverityInfo},
+		{"", SeverityInfo},
+		{"bogus", SeverityInfo},
+	}
+
+	for _, tt := range tests {
+		if got := severityFromString(tt.in); got != tt.want {
+			t.Errorf("severityFromString(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLoggerSeverityFiltering(t *testing.T) {
+	var out, errOut bytes.Buffer
+	logger := New(&out, &errOut, SeverityWarning, 0)
+
+	logger.Info("should be dropped")
+	if out.Len() != 0 {
+		t.Errorf("Info logged below the SeverityWarning floor: %q", out.String())
+	}
+
+	logger.Warning("should appear")
+	if !strings.Contains(out.String(), "should appear") {
+		t.Errorf("Warning at the floor was dropped, got %q", out.String())
+	}
+
+	logger.Error("boom")
+	if !strings.Contains(errOut.String(), "boom") {
+		t.Errorf("Error above the floor was dropped, got %q", errOut.String())
+	}
+}
+
+func TestLoggerVerbosity(t *testing.T) {
+	var out, errOut bytes.Buffer
+	logger := New(&out, &errOut, SeverityInfo, 2)
+
+	if !logger.V(0) {
+		t.Error("V(0) should be enabled when verbosity level is 2")
+	}
+	if !logger.V(2) {
+		t.Error("V(2) should be enabled when verbosity level is 2")
+	}
+	if logger.V(3) {
+		t.Error("V(3) should be disabled when verbosity level is 2")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2019 Minoru Osuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"time"
+
+	"google.golang.org/grpc/keepalive"
+)
+
+// defaultMaxMsgSize replaces gRPC's stock 4MiB cap.
+const defaultMaxMsgSize = 16 * 1024 * 1024
+
+// GRPCServerTuning holds the gRPC server's keepalive and message-size knobs.
+type GRPCServerTuning struct {
+	Keepalive         keepalive.ServerParameters
+	EnforcementPolicy keepalive.EnforcementPolicy
+	MaxRecvMsgSize    int
+	MaxSendMsgSize    int
+}
+
+// GRPCClientTuning is the dial-side counterpart used on the join/leave paths.
+type GRPCClientTuning struct {
+	Keepalive      keepalive.ClientParameters
+	DialTimeout    time.Duration
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+}
+
+// NewDefaultGRPCServerTuning returns blast's default server tuning.
+func NewDefaultGRPCServerTuning() *GRPCServerTuning {
+	return &GRPCServerTuning{
+		Keepalive: keepalive.ServerParameters{
+			MaxConnectionIdle: 15 * time.Minute,
+			MaxConnectionAge:  2 * time.Hour,
+			Time:              2 * time.Hour,
+			Timeout:           20 * time.Second,
+		},
+		EnforcementPolicy: keepalive.EnforcementPolicy{
+			MinTime:             5 * time.Minute,
+			PermitWithoutStream: true,
+		},
+		MaxRecvMsgSize: defaultMaxMsgSize,
+		MaxSendMsgSize: defaultMaxMsgSize,
+	}
+}
+
+// NewDefaultGRPCClientTuning returns blast's default client tuning.
+func NewDefaultGRPCClientTuning() *GRPCClientTuning {
+	return &GRPCClientTuning{
+		Keepalive: keepalive.ClientParameters{
+			Time:                2 * time.Hour,
+			Timeout:             20 * time.Second,
+			PermitWithoutStream: true,
+		},
+		DialTimeout:    10 * time.Second,
+		MaxRecvMsgSize: defaultMaxMsgSize,
+		MaxSendMsgSize: defaultMaxMsgSize,
+	}
+}
@@ -15,13 +15,28 @@
 package manager
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	multierror "github.com/hashicorp/go-multierror"
 	accesslog "github.com/mash/go-accesslog"
 	"github.com/mosuka/blast/grpc"
 	"github.com/mosuka/blast/http"
+	"github.com/mosuka/blast/log"
+	grpclog "google.golang.org/grpc/grpclog"
 )
 
+// defaultDrainTimeout is the deadline the SIGINT/SIGTERM handler in Start
+// gives Shutdown when NewServer is passed no positive drainTimeout.
+const defaultDrainTimeout = 10 * time.Second
+
+// defaultStopTimeout bounds Stop, which has no caller-supplied context.
+const defaultStopTimeout = 5 * time.Second
+
 type Server struct {
 	id       string
 	metadata map[string]interface{}
@@ -30,24 +45,53 @@ type Server struct {
 
 	indexConfig map[string]interface{}
 
-	raftServer  *RaftServer
-	grpcService *GRPCService
-	grpcServer  *grpc.Server
-	httpRouter  *http.Router
-	httpServer  *http.Server
+	tlsConfig        *TLSConfig
+	serverOptions    *ServerOptions
+	drainTimeout     time.Duration
+	grpcServerTuning *GRPCServerTuning
+	grpcClientTuning *GRPCClientTuning
+
+	raftServer  raftServerHandle
+	grpcService grpcServiceHandle
+	grpcServer  grpcServerHandle
+	httpRouter  httpRouterHandle
+	httpServer  httpServerHandle
 
-	logger     *log.Logger
+	logger     log.Logger
 	httpLogger accesslog.Logger
 }
 
-func NewServer(id string, metadata map[string]interface{}, peerAddr string, indexConfig map[string]interface{}, logger *log.Logger, httpLogger accesslog.Logger) (*Server, error) {
+func NewServer(id string, metadata map[string]interface{}, peerAddr string, indexConfig map[string]interface{}, tlsConfig *TLSConfig, serverOptions *ServerOptions, grpcServerTuning *GRPCServerTuning, grpcClientTuning *GRPCClientTuning, drainTimeout time.Duration, logger log.Logger, httpLogger accesslog.Logger) (*Server, error) {
+	if serverOptions == nil {
+		serverOptions = NewServerOptions("", false, logger)
+	}
+	if grpcServerTuning == nil {
+		grpcServerTuning = NewDefaultGRPCServerTuning()
+	}
+	if grpcClientTuning == nil {
+		grpcClientTuning = NewDefaultGRPCClientTuning()
+	}
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+
+	// route the gRPC library's own diagnostics through the same
+	// façade so BLAST_LOG_SEVERITY_LEVEL filters them and
+	// BLAST_LOG_VERBOSITY_LEVEL gates their V-level lines.
+	grpclog.SetLoggerV2(log.AsGRPCLoggerV2(logger))
+
 	return &Server{
-		id:          id,
-		metadata:    metadata,
-		peerAddr:    peerAddr,
-		indexConfig: indexConfig,
-		logger:      logger,
-		httpLogger:  httpLogger,
+		id:               id,
+		metadata:         metadata,
+		peerAddr:         peerAddr,
+		indexConfig:      indexConfig,
+		tlsConfig:        tlsConfig,
+		serverOptions:    serverOptions,
+		grpcServerTuning: grpcServerTuning,
+		grpcClientTuning: grpcClientTuning,
+		drainTimeout:     drainTimeout,
+		logger:           logger,
+		httpLogger:       httpLogger,
 	}, nil
 }
 
@@ -56,131 +100,217 @@ func (s *Server) Start() {
 
 	// bootstrap node?
 	bootstrap := s.peerAddr == ""
-	s.logger.Printf("[INFO] bootstrap: %v", bootstrap)
+	s.logger.Infof("bootstrap: %v", bootstrap)
 
 	// create raft server
-	s.raftServer, err = NewRaftServer(s.id, s.metadata, bootstrap, s.indexConfig, s.logger)
+	s.raftServer, err = NewRaftServer(s.id, s.metadata, bootstrap, s.indexConfig, s.tlsConfig, s.logger)
 	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+		s.logger.Errorf("%v", err)
 		return
 	}
 
 	// create gRPC service
 	s.grpcService, err = NewGRPCService(s.raftServer, s.logger)
 	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+		s.logger.Errorf("%v", err)
 		return
 	}
 
 	// create gRPC server
-	s.grpcServer, err = grpc.NewServer(s.metadata["grpc_addr"].(string), s.grpcService, s.logger)
+	s.grpcServer, err = grpc.NewServer(s.metadata["grpc_addr"].(string), s.grpcService, s.tlsConfig, s.serverOptions, s.grpcServerTuning, s.logger)
 	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+		s.logger.Errorf("%v", err)
 		return
 	}
 
-	// create HTTP router
-	s.httpRouter, err = NewRouter(s.metadata["grpc_addr"].(string), s.logger)
+	// create HTTP router. TODO(chunk0-6): still the hand-written Router;
+	// protobuf/management.proto exists but nothing generates or mounts a
+	// grpc-gateway mux from it yet, so that request isn't done.
+	s.httpRouter, err = NewRouter(s.metadata["grpc_addr"].(string), s.tlsConfig, s.serverOptions, s.logger)
 	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+		s.logger.Errorf("%v", err)
 		return
 	}
 
 	// create HTTP server
-	s.httpServer, err = http.NewServer(s.metadata["http_addr"].(string), s.httpRouter, s.logger, s.httpLogger)
+	s.httpServer, err = http.NewServer(s.metadata["http_addr"].(string), s.httpRouter, s.tlsConfig, s.logger, s.httpLogger)
 	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+		s.logger.Errorf("%v", err)
 		return
 	}
 
 	// start Raft server
-	s.logger.Print("[INFO] start Raft server")
+	s.logger.Info("start Raft server")
 	err = s.raftServer.Start()
 	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+		s.logger.Errorf("%v", err)
 		return
 	}
 
 	// start gRPC service
-	s.logger.Print("[INFO] start gRPC service")
+	s.logger.Info("start gRPC service")
 	go func() {
 		err := s.grpcService.Start()
 		if err != nil {
-			s.logger.Printf("[ERR] %v", err)
+			s.logger.Errorf("%v", err)
 			return
 		}
 	}()
 
 	// start gRPC server
-	s.logger.Print("[INFO] start gRPC server")
+	s.logger.Info("start gRPC server")
 	go func() {
 		err := s.grpcServer.Start()
 		if err != nil {
-			s.logger.Printf("[ERR] %v", err)
+			s.logger.Errorf("%v", err)
 			return
 		}
 	}()
 
 	// start HTTP server
-	s.logger.Print("[INFO] start HTTP server")
+	s.logger.Info("start HTTP server")
 	go func() {
 		_ = s.httpServer.Start()
 	}()
 
 	// join to the existing cluster
 	if !bootstrap {
-		client, err := grpc.NewClient(s.peerAddr)
+		client, err := grpc.NewClient(s.peerAddr, s.tlsConfig, s.grpcClientTuning)
 		defer func() {
 			err := client.Close()
 			if err != nil {
-				s.logger.Printf("[ERR] %v", err)
+				s.logger.Errorf("%v", err)
 			}
 		}()
 		if err != nil {
-			s.logger.Printf("[ERR] %v", err)
+			s.logger.Errorf("%v", err)
 			return
 		}
 
 		err = client.SetNode(s.id, s.metadata)
 		if err != nil {
-			s.logger.Printf("[ERR] %v", err)
+			s.logger.Errorf("%v", err)
 			return
 		}
 	}
+
+	// handle SIGINT/SIGTERM with a graceful shutdown instead of letting
+	// the process die mid-RPC.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigCh
+		s.logger.Infof("received signal: %v", sig)
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.drainTimeout)
+		defer cancel()
+
+		if err := s.Shutdown(ctx); err != nil {
+			s.logger.Errorf("%v", err)
+		}
+	}()
 }
 
-func (s *Server) Stop() {
+// Shutdown stops accepting new gRPC/HTTP connections, gives in-flight
+// requests until ctx is done to finish, removes this node from the Raft
+// configuration if it is still a member of it, and only then
+// stops the Raft server. Unlike Stop, it returns the aggregate error
+// from every subsystem instead of only logging it.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var result *multierror.Error
+
 	// stop HTTP server
-	s.logger.Print("[INFO] stop HTTP server")
-	err := s.httpServer.Stop()
-	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+	s.logger.Info("stop HTTP server")
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		result = multierror.Append(result, err)
 	}
 
 	// stop HTTP router
-	err = s.httpRouter.Close()
-	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+	if err := s.httpRouter.Close(); err != nil {
+		result = multierror.Append(result, err)
 	}
 
-	// stop gRPC server
-	s.logger.Print("[INFO] stop gRPC server")
-	err = s.grpcServer.Stop()
-	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+	// stop gRPC server, draining in-flight RPCs until ctx is done
+	s.logger.Info("stop gRPC server")
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		result = multierror.Append(result, fmt.Errorf("gRPC server did not drain before deadline: %w", ctx.Err()))
+		if err := s.grpcServer.Stop(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	// leave the cluster cleanly before tearing down Raft, so the
+	// remaining nodes never see this one as merely unreachable. Gated
+	// on actual Raft membership, not on how this node originally
+	// joined: the bootstrap node never had a peerAddr but is just as
+	// much a voter as any node that joined it later.
+	if inCluster, err := s.raftServer.IsClusterMember(s.id); err != nil {
+		result = multierror.Append(result, err)
+	} else if inCluster {
+		if err := s.leaveCluster(); err != nil {
+			result = multierror.Append(result, err)
+		}
 	}
 
 	// stop gRPC service
-	s.logger.Print("[INFO] stop gRPC service")
-	err = s.grpcService.Stop()
-	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+	s.logger.Info("stop gRPC service")
+	if err := s.grpcService.Stop(); err != nil {
+		result = multierror.Append(result, err)
 	}
 
 	// stop Raft server
-	s.logger.Print("[INFO] stop Raft server")
-	err = s.raftServer.Stop()
+	s.logger.Info("stop Raft server")
+	if err := s.raftServer.Stop(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	return result.ErrorOrNil()
+}
+
+// leaveCluster asks the current Raft leader to remove this node from the
+// cluster configuration via GRPCService's LeaveNode RPC. If this node is
+// itself the leader, it removes itself directly through raftServer
+// instead of dialing out: by the time Shutdown gets here, this node's
+// own gRPC server is already draining/stopped, so a self-dial would
+// fail and the node would never leave the configuration cleanly.
+func (s *Server) leaveCluster() error {
+	leaderAddr, err := s.raftServer.LeaderAddress()
+	if err != nil {
+		return fmt.Errorf("failed to resolve Raft leader for LeaveNode: %w", err)
+	}
+
+	if leaderAddr == s.metadata["grpc_addr"].(string) {
+		return s.raftServer.RemoveNode(s.id)
+	}
+
+	client, err := grpc.NewClient(leaderAddr, s.tlsConfig, s.grpcClientTuning)
 	if err != nil {
-		s.logger.Printf("[ERR] %v", err)
+		return err
 	}
-}
\ No newline at end of file
+	defer func() {
+		if err := client.Close(); err != nil {
+			s.logger.Errorf("%v", err)
+		}
+	}()
+
+	return client.LeaveNode(s.id)
+}
+
+// Stop tears down HTTP, gRPC and Raft, forcing the gRPC server closed
+// after defaultStopTimeout. Prefer Shutdown when a caller can supply its
+// own context and drain deadline.
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultStopTimeout)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); err != nil {
+		s.logger.Errorf("%v", err)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2019 Minoru Osuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import "context"
+
+// raftServerHandle is the subset of *RaftServer that Server depends on,
+// pulled out as an interface so Shutdown/leaveCluster can be tested
+// against a fake instead of a real Raft cluster.
+type raftServerHandle interface {
+	Start() error
+	Stop() error
+	LeaderAddress() (string, error)
+	IsClusterMember(id string) (bool, error)
+	RemoveNode(id string) error
+}
+
+// grpcServiceHandle is the subset of *GRPCService that Server depends on.
+type grpcServiceHandle interface {
+	Start() error
+	Stop() error
+}
+
+// grpcServerHandle is the subset of *grpc.Server that Server depends on.
+type grpcServerHandle interface {
+	Start() error
+	Stop() error
+	GracefulStop()
+}
+
+// httpServerHandle is the subset of *http.Server that Server depends on.
+type httpServerHandle interface {
+	Start() error
+	Stop() error
+	Shutdown(ctx context.Context) error
+}
+
+// httpRouterHandle is the subset of *http.Router that Server depends on.
+type httpRouterHandle interface {
+	Close() error
+}
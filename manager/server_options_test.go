@@ -0,0 +1,109 @@
+// Copyright (c) 2019 Minoru Osuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	blastlog "github.com/mosuka/blast/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func testLogger() blastlog.Logger {
+	var out bytes.Buffer
+	return blastlog.New(&out, &out, blastlog.SeverityInfo, 0)
+}
+
+func TestRecoveryUnaryInterceptorConvertsPanicToInternal(t *testing.T) {
+	interceptor := recoveryUnaryInterceptor(testLogger())
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/Test/Method"}, handler)
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected codes.Internal after a panic, got %v", err)
+	}
+}
+
+func TestRecoveryUnaryInterceptorPassesThroughOnSuccess(t *testing.T) {
+	interceptor := recoveryUnaryInterceptor(testLogger())
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp != "ok" {
+		t.Fatalf("expected handler response to pass through, got %v", resp)
+	}
+}
+
+func TestAuthUnaryInterceptor(t *testing.T) {
+	interceptor := authUnaryInterceptor("secret")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/Test/Method"}
+
+	t.Run("missing metadata", func(t *testing.T) {
+		_, err := interceptor(context.Background(), nil, info, handler)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("expected codes.Unauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer wrong"))
+		_, err := interceptor(ctx, nil, info, handler)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("expected codes.Unauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("correct token", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret"))
+		resp, err := interceptor(ctx, nil, info, handler)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp != "ok" {
+			t.Fatalf("expected handler response to pass through, got %v", resp)
+		}
+	})
+}
+
+func TestNewServerOptionsChainComposition(t *testing.T) {
+	logger := testLogger()
+
+	plain := NewServerOptions("", false, logger)
+	if len(plain.UnaryInterceptors) != 2 {
+		t.Fatalf("expected recovery+logging only, got %d interceptors", len(plain.UnaryInterceptors))
+	}
+
+	full := NewServerOptions("secret", true, logger)
+	if len(full.UnaryInterceptors) != 4 {
+		t.Fatalf("expected recovery+auth+metrics+logging, got %d interceptors", len(full.UnaryInterceptors))
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Minoru Osuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeRaftServer struct {
+	leaderAddr       string
+	isMember         bool
+	removeNodeCalled bool
+	stopCalled       bool
+}
+
+func (f *fakeRaftServer) Start() error { return nil }
+func (f *fakeRaftServer) Stop() error  { f.stopCalled = true; return nil }
+func (f *fakeRaftServer) LeaderAddress() (string, error) {
+	return f.leaderAddr, nil
+}
+func (f *fakeRaftServer) IsClusterMember(id string) (bool, error) {
+	return f.isMember, nil
+}
+func (f *fakeRaftServer) RemoveNode(id string) error {
+	f.removeNodeCalled = true
+	return nil
+}
+
+type fakeGRPCService struct{ stopCalled bool }
+
+func (f *fakeGRPCService) Start() error { return nil }
+func (f *fakeGRPCService) Stop() error  { f.stopCalled = true; return nil }
+
+type fakeGRPCServer struct {
+	block           chan struct{}
+	forceStopCalled bool
+}
+
+func (f *fakeGRPCServer) Start() error { return nil }
+func (f *fakeGRPCServer) Stop() error  { f.forceStopCalled = true; return nil }
+func (f *fakeGRPCServer) GracefulStop() {
+	if f.block != nil {
+		<-f.block
+	}
+}
+
+type fakeHTTPServer struct{}
+
+func (f *fakeHTTPServer) Start() error                       { return nil }
+func (f *fakeHTTPServer) Stop() error                        { return nil }
+func (f *fakeHTTPServer) Shutdown(ctx context.Context) error { return nil }
+
+type fakeHTTPRouter struct{}
+
+func (f *fakeHTTPRouter) Close() error { return nil }
+
+func newTestServer(raft *fakeRaftServer, grpcSvc *fakeGRPCService, grpcSrv *fakeGRPCServer) *Server {
+	return &Server{
+		id:          "node1",
+		metadata:    map[string]interface{}{"grpc_addr": "127.0.0.1:10000"},
+		raftServer:  raft,
+		grpcService: grpcSvc,
+		grpcServer:  grpcSrv,
+		httpRouter:  &fakeHTTPRouter{},
+		httpServer:  &fakeHTTPServer{},
+		logger:      testLogger(),
+	}
+}
+
+func TestShutdownSelfLeaderRemovesNodeDirectly(t *testing.T) {
+	raft := &fakeRaftServer{leaderAddr: "127.0.0.1:10000", isMember: true}
+	s := newTestServer(raft, &fakeGRPCService{}, &fakeGRPCServer{})
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !raft.removeNodeCalled {
+		t.Error("expected leaveCluster to remove the node directly when it is the leader, not dial itself")
+	}
+}
+
+func TestShutdownForcesGRPCStopOnDeadlineExceeded(t *testing.T) {
+	raft := &fakeRaftServer{leaderAddr: "127.0.0.1:10000", isMember: false}
+	grpcSrv := &fakeGRPCServer{block: make(chan struct{})}
+	s := newTestServer(raft, &fakeGRPCService{}, grpcSrv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := s.Shutdown(ctx)
+	if err == nil {
+		t.Fatal("expected an error when the gRPC server doesn't drain before the deadline")
+	}
+	if !grpcSrv.forceStopCalled {
+		t.Error("expected grpcServer.Stop to be called as a force-stop after the deadline")
+	}
+}
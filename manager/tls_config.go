@@ -0,0 +1,53 @@
+// Copyright (c) 2019 Minoru Osuka
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import "crypto/tls"
+
+// ClientAuthType is the config/flag-friendly form of tls.ClientAuthType.
+type ClientAuthType int
+
+const (
+	ClientAuthNone ClientAuthType = iota
+	ClientAuthVerifyIfGiven
+	ClientAuthRequireAndVerify
+)
+
+// ToStdlib converts to tls.ClientAuthType for tls.Config.ClientAuth.
+func (t ClientAuthType) ToStdlib() tls.ClientAuthType {
+	switch t {
+	case ClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven
+	case ClientAuthRequireAndVerify:
+		return tls.RequireAndVerifyClientCert
+	default:
+		return tls.NoClientCert
+	}
+}
+
+// TLSConfig holds the cert material and client-auth mode shared by the
+// gRPC server, the join-path gRPC client, the HTTP server and Raft.
+type TLSConfig struct {
+	CertFile   string
+	KeyFile    string
+	CAFile     string
+	ServerName string
+	ClientAuth ClientAuthType
+}
+
+// Enabled reports whether TLS has been configured at all.
+func (c *TLSConfig) Enabled() bool {
+	return c != nil && c.CertFile != "" && c.KeyFile != ""
+}